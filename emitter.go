@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	core "k8s.io/api/core/v1"
+
+	"github.com/egymgmbh/kubejob/pkg/job"
+)
+
+// eventEmitter renders job.Events for output, either as human-readable log lines (the default) or as
+// newline-delimited JSON records for machine consumption (--output=json).
+type eventEmitter interface {
+	// Emit renders a single job.Event.
+	Emit(event job.Event)
+	// Result renders the final outcome of the job once RunJob has returned.
+	Result(success bool, err error)
+}
+
+// newEventEmitter returns the eventEmitter for the requested output format ("text" or "json").
+func newEventEmitter(output string, out io.Writer) (eventEmitter, error) {
+	switch output {
+	case "", "text":
+		return &textEmitter{lastPhase: make(map[string]core.PodPhase)}, nil
+	case "json":
+		return &jsonEmitter{enc: json.NewEncoder(out), lastPhase: make(map[string]core.PodPhase), lastContainerState: make(map[string]string)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", output)
+	}
+}
+
+// textEmitter renders events as human-readable log.Print lines, matching kubejob's historical behaviour.
+type textEmitter struct {
+	lastPhase map[string]core.PodPhase
+}
+
+func (e *textEmitter) Emit(event job.Event) {
+	switch event := event.(type) {
+	case error:
+		log.Printf("Error: %v", event)
+	case job.LogLine:
+		log.Printf("%s/%s: %s", event.Pod, event.Container, event.Line)
+	case job.LogStreamReconnect:
+		log.Printf("%s/%s: reconnecting log stream (attempt %d): %v", event.Pod, event.Container, event.Attempt, event.Cause)
+	case job.JobStatus:
+		log.Printf("Job status: active=%d succeeded=%d failed=%d", event.Active, event.Succeeded, event.Failed)
+	case job.PodStatus:
+		if event.Phase != e.lastPhase[event.Pod] {
+			log.Printf("%s: Phase: %s", event.Pod, event.Phase)
+		}
+		e.lastPhase[event.Pod] = event.Phase
+
+		if event.Phase == core.PodPending {
+			for _, cs := range event.ContainerStatuses {
+				if cs.State.Waiting != nil {
+					log.Printf("%s: Container %s is waiting: %s", event.Pod, cs.Name, cs.State.Waiting.Reason)
+				}
+			}
+		}
+	}
+}
+
+func (e *textEmitter) Result(success bool, err error) {
+	if success {
+		log.Print("Job completed successfully")
+	} else {
+		log.Print("Job failed")
+	}
+	if err != nil {
+		log.Print("Error: ", err)
+	}
+}
+
+// jsonRecord is one NDJSON record emitted in --output=json mode. Payload's shape depends on Type: a string for
+// "log"/"error" and for a pod's "phase", a batch.JobStatus for the job-level "phase" record (which carries no Pod),
+// or one of containerStatePayload/resultPayload for "container_state"/"result".
+type jsonRecord struct {
+	Type      string      `json:"type"`
+	Pod       string      `json:"pod,omitempty"`
+	Container string      `json:"container,omitempty"`
+	Time      time.Time   `json:"time"`
+	Payload   interface{} `json:"payload,omitempty"`
+}
+
+type containerStatePayload struct {
+	State    string `json:"state"` // waiting, running or terminated
+	Reason   string `json:"reason,omitempty"`
+	ExitCode *int32 `json:"exitCode,omitempty"`
+}
+
+type resultPayload struct {
+	Success bool   `json:"success"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// jsonEmitter renders events as newline-delimited JSON records so kubejob can be composed into pipelines.
+type jsonEmitter struct {
+	enc *json.Encoder
+
+	lastPhase          map[string]core.PodPhase
+	lastContainerState map[string]string // keyed by "<pod>/<container>"
+}
+
+func (e *jsonEmitter) Emit(event job.Event) {
+	switch event := event.(type) {
+	case error:
+		e.write(jsonRecord{Type: "error", Payload: event.Error()})
+	case job.LogLine:
+		e.write(jsonRecord{Type: "log", Pod: event.Pod, Container: event.Container, Payload: event.Line})
+	case job.LogStreamReconnect:
+		e.write(jsonRecord{
+			Type:      "error",
+			Pod:       event.Pod,
+			Container: event.Container,
+			Payload:   fmt.Sprintf("reconnecting log stream (attempt %d): %v", event.Attempt, event.Cause),
+		})
+	case job.JobStatus:
+		e.write(jsonRecord{Type: "phase", Payload: event.JobStatus})
+	case job.PodStatus:
+		if event.Phase != e.lastPhase[event.Pod] {
+			e.write(jsonRecord{Type: "phase", Pod: event.Pod, Payload: string(event.Phase)})
+		}
+		e.lastPhase[event.Pod] = event.Phase
+
+		for _, cs := range event.ContainerStatuses {
+			state, reason, exitCode := containerState(cs)
+			if state == "" {
+				continue
+			}
+
+			key := event.Pod + "/" + cs.Name
+			if e.lastContainerState[key] == state {
+				continue
+			}
+			e.lastContainerState[key] = state
+
+			e.write(jsonRecord{
+				Type:      "container_state",
+				Pod:       event.Pod,
+				Container: cs.Name,
+				Payload:   containerStatePayload{State: state, Reason: reason, ExitCode: exitCode},
+			})
+		}
+	}
+}
+
+func (e *jsonEmitter) Result(success bool, err error) {
+	var reason string
+	if err != nil {
+		reason = err.Error()
+	}
+	e.write(jsonRecord{Type: "result", Payload: resultPayload{Success: success, Reason: reason}})
+}
+
+func (e *jsonEmitter) write(r jsonRecord) {
+	r.Time = time.Now().UTC()
+	if err := e.enc.Encode(r); err != nil {
+		log.Printf("json emitter: %v", err)
+	}
+}
+
+// containerState classifies a container's current state for JSON output, returning an empty state if cs carries no
+// state at all (which shouldn't normally happen once a pod has been scheduled).
+func containerState(cs core.ContainerStatus) (state, reason string, exitCode *int32) {
+	switch {
+	case cs.State.Terminated != nil:
+		ec := cs.State.Terminated.ExitCode
+		return "terminated", cs.State.Terminated.Reason, &ec
+	case cs.State.Waiting != nil:
+		return "waiting", cs.State.Waiting.Reason, nil
+	case cs.State.Running != nil:
+		return "running", "", nil
+	default:
+		return "", "", nil
+	}
+}
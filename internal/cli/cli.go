@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"path/filepath"
 	"text/template"
 	"time"
 
@@ -23,13 +22,18 @@ Examples:
 `
 
 type Args struct {
-	Kubeconfig string
+	Kubeconfig string // (optional) explicit path to a kubeconfig file, see k8sClientSet for the fallback order
+	Context    string // (optional) kubeconfig context to use instead of its current-context
+	InCluster  bool   // use the in-cluster config instead of a kubeconfig file
 	Namespace  string
 	JobFile    string
 	Timeout    time.Duration
+	LogsDir    string // local directory to archive container logs to, empty disables local archiving
+	LogsURI    string // s3:// or gs:// URI to archive container logs to, empty disables remote archiving
+	Output     string // "text" (default) or "json"
 }
 
-func Parse(args []string, home, version string, out io.Writer) (*Args, error) {
+func Parse(args []string, version string, out io.Writer) (*Args, error) {
 	var help bytes.Buffer
 	err := template.Must(template.New("help").Parse(helpTemplate)).Execute(&help, map[string]string{"app": args[0]})
 	if err != nil {
@@ -43,13 +47,12 @@ func Parse(args []string, home, version string, out io.Writer) (*Args, error) {
 	jobFile := app.Arg("JOBFILE", "Job spec file, - for stdin (default)").Default("-").String()
 	namespace := app.Flag("namespace", "Kubernetes namespace to use").Short('n').Required().String()
 	timeout := app.Flag("timeout", "Timeout in time.Duration format (eg. 10s, 1m, 1h, ...)").Short('t').Duration()
-
-	var kubeconfig *string
-	if home != "" {
-		kubeconfig = app.Flag("kubeconfig", "(optional) absolute path to the Kubeconfig file").Default(filepath.Join(home, ".kube", "config")).String()
-	} else {
-		kubeconfig = app.Flag("kubeconfig", "absolute path to the Kubeconfig file").Required().String()
-	}
+	logsDir := app.Flag("logs-dir", "(optional) local directory to archive each container's logs to").String()
+	logsURI := app.Flag("logs-uri", "(optional) s3:// or gs:// URI to archive each container's logs to").String()
+	output := app.Flag("output", "Event output format").Default("text").Enum("text", "json")
+	kubeconfig := app.Flag("kubeconfig", "(optional) absolute path to the kubeconfig file, defaults to $KUBECONFIG or $HOME/.kube/config").String()
+	kubeContext := app.Flag("context", "(optional) kubeconfig context to use instead of its current-context").String()
+	inCluster := app.Flag("in-cluster", "Use the in-cluster config instead of a kubeconfig file (auto-detected when KUBERNETES_SERVICE_HOST is set)").Bool()
 
 	// do not call os.Exit() on error
 	app.Terminate(nil)
@@ -65,8 +68,13 @@ func Parse(args []string, home, version string, out io.Writer) (*Args, error) {
 
 	return &Args{
 		Kubeconfig: *kubeconfig,
+		Context:    *kubeContext,
+		InCluster:  *inCluster,
 		Namespace:  *namespace,
 		JobFile:    *jobFile,
 		Timeout:    *timeout,
+		LogsDir:    *logsDir,
+		LogsURI:    *logsURI,
+		Output:     *output,
 	}, nil
 }
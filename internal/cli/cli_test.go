@@ -1,51 +1,117 @@
 package cli
 
 import (
-	"testing"
-	"reflect"
 	"bytes"
+	"reflect"
 	"strings"
+	"testing"
 )
 
 func TestParse(t *testing.T) {
-	data := []struct{
-		home string
-		args []string
+	data := []struct {
+		args        []string
 		expectedRes Args
 		expectedErr bool
 		outContains string
 	}{
 		{
-			"/foo/bar",
 			[]string{"kubejob", "-n", "foo"},
 			Args{
-				Kubeconfig: "/foo/bar/.kube/config",
 				Namespace: "foo",
-				JobFile: "-",
+				JobFile:   "-",
+				Output:    "text",
 			},
 			false,
 			"",
 		},
 		{
-			"/foo/bar",
 			[]string{"kubejob", "-n", "foo", "/fizz/buzz"},
+			Args{
+				Namespace: "foo",
+				JobFile:   "/fizz/buzz",
+				Output:    "text",
+			},
+			false,
+			"",
+		},
+		{
+			[]string{"kubejob", "-n", "foo", "--logs-dir", "/tmp/logs"},
+			Args{
+				Namespace: "foo",
+				JobFile:   "-",
+				LogsDir:   "/tmp/logs",
+				Output:    "text",
+			},
+			false,
+			"",
+		},
+		{
+			[]string{"kubejob", "-n", "foo", "--logs-uri", "s3://bucket/prefix"},
+			Args{
+				Namespace: "foo",
+				JobFile:   "-",
+				LogsURI:   "s3://bucket/prefix",
+				Output:    "text",
+			},
+			false,
+			"",
+		},
+		{
+			[]string{"kubejob", "-n", "foo", "--output", "json"},
+			Args{
+				Namespace: "foo",
+				JobFile:   "-",
+				Output:    "json",
+			},
+			false,
+			"",
+		},
+		{
+			[]string{"kubejob", "-n", "foo", "--output", "xml"},
+			Args{},
+			true,
+			"",
+		},
+		{
+			[]string{"kubejob", "-n", "foo", "--kubeconfig", "/foo/bar/.kube/config"},
 			Args{
 				Kubeconfig: "/foo/bar/.kube/config",
+				Namespace:  "foo",
+				JobFile:    "-",
+				Output:     "text",
+			},
+			false,
+			"",
+		},
+		{
+			[]string{"kubejob", "-n", "foo", "--context", "other-cluster"},
+			Args{
 				Namespace: "foo",
-				JobFile: "/fizz/buzz",
+				JobFile:   "-",
+				Context:   "other-cluster",
+				Output:    "text",
+			},
+			false,
+			"",
+		},
+		{
+			[]string{"kubejob", "-n", "foo", "--in-cluster"},
+			Args{
+				Namespace: "foo",
+				JobFile:   "-",
+				InCluster: true,
+				Output:    "text",
 			},
 			false,
 			"",
 		},
 		{
-			"/foo/bar",
 			[]string{"kubejob", "--help"},
 			Args{},
 			true,
 			"",
 		},
 		{
-			"",
 			[]string{"kubejob", "--version"},
 			Args{},
 			true,
@@ -55,7 +121,7 @@ func TestParse(t *testing.T) {
 
 	for _, d := range data {
 		var buf bytes.Buffer
-		res, err := Parse(d.args, d.home, "42-23-73", &buf)
+		res, err := Parse(d.args, "42-23-73", &buf)
 		if d.expectedErr {
 			if err == nil {
 				t.Fatalf("%v: missing expected error", d)
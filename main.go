@@ -4,18 +4,24 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws/session"
+
 	batch "k8s.io/api/batch/v1"
 	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp" // enable GCP specific authentication
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
+	"github.com/egymgmbh/kubejob/pkg/artifacts"
 	"github.com/egymgmbh/kubejob/pkg/cli"
 	"github.com/egymgmbh/kubejob/pkg/job"
 )
@@ -23,7 +29,7 @@ import (
 var githash string // set by linker, see '.travis.yml'
 
 func main() {
-	args, err := cli.Parse(os.Args, os.Getenv("HOME"), githash, os.Stderr)
+	args, err := cli.Parse(os.Args, githash, os.Stderr)
 	if err != nil {
 		log.Fatal("Error: ", err)
 	}
@@ -53,48 +59,49 @@ func main() {
 		log.Fatal("Unable to parse job: ", err)
 	}
 
-	cs, err := k8sClientSet(args.Kubeconfig)
+	cs, err := k8sClientSet(args)
 	if err != nil {
 		log.Fatal("Failed to create client: ", err)
 	}
 
+	var recorder *artifacts.Recorder
+	store, err := newArtifactStore(args)
+	if err != nil {
+		log.Fatal("Unable to set up log archiving: ", err)
+	}
+	if store != nil {
+		recorder = artifacts.NewRecorder(store, jobSpec.Name)
+	}
+
+	emitter, err := newEventEmitter(args.Output, os.Stdout)
+	if err != nil {
+		log.Fatal("Error: ", err)
+	}
+
 	events := make(chan job.Event)
+	consumerDone := make(chan struct{})
 	go func() {
-		var lastPhase core.PodPhase
+		defer close(consumerDone)
+
 		for event := range events {
-			switch event := event.(type) {
-			case error:
-				log.Printf("Error: %v", event)
-			case job.LogLine:
-				log.Printf("%s: %s", event.Container, event.Line)
-			case core.PodStatus:
-				status := event
-				if status.Phase != lastPhase {
-					log.Print("Phase: ", status.Phase)
-				}
-				lastPhase = status.Phase
-
-				if status.Phase == core.PodPending {
-					for _, cs := range status.ContainerStatuses {
-						if cs.State.Waiting != nil {
-							log.Printf("Container %s is waiting: %s", cs.Name, cs.State.Waiting.Reason)
-						}
-					}
-				}
+			if recorder != nil {
+				recorder.Handle(event)
 			}
+			emitter.Emit(event)
 		}
 	}()
 
 	success, err := job.RunJob(ctx, cs, args.Namespace, jobSpec, events)
-	if success {
-		log.Print("Job completed successfully")
-	} else {
-		log.Print("Job failed")
-	}
-	if err != nil {
-		log.Print("Error: ", err)
+	<-consumerDone
+
+	if recorder != nil {
+		if ferr := recorder.Finalize(); ferr != nil {
+			log.Print("Archiving logs: ", ferr)
+		}
 	}
 
+	emitter.Result(success, err)
+
 	log.Print("Deleting job")
 	err = cs.BatchV1().Jobs(args.Namespace).Delete(jobSpec.Name, nil)
 	if err != nil {
@@ -106,6 +113,36 @@ func main() {
 	}
 }
 
+// newArtifactStore builds the artifacts.Store requested via --logs-dir/--logs-uri, or returns a nil store if neither
+// was given. --logs-uri takes precedence if both are set.
+func newArtifactStore(args *cli.Args) (artifacts.Store, error) {
+	switch {
+	case args.LogsURI != "":
+		u, err := url.Parse(args.LogsURI)
+		if err != nil {
+			return nil, fmt.Errorf("invalid logs-uri: %v", err)
+		}
+		prefix := strings.TrimPrefix(u.Path, "/")
+
+		switch u.Scheme {
+		case "s3":
+			sess, err := session.NewSession()
+			if err != nil {
+				return nil, fmt.Errorf("creating AWS session: %v", err)
+			}
+			return artifacts.NewS3Store(sess, u.Host, prefix), nil
+		case "gs":
+			return artifacts.NewGCSStore(context.Background(), u.Host, prefix)
+		default:
+			return nil, fmt.Errorf("unsupported logs-uri scheme %q", u.Scheme)
+		}
+	case args.LogsDir != "":
+		return artifacts.NewLocalStore(args.LogsDir), nil
+	default:
+		return nil, nil
+	}
+}
+
 // parseAndValidateJob reads the job spec from path and returns the result if possible. If path is "-" the job spec is
 // read from os.Stdin. Warnings are logged if required values are not set.
 func parseAndValidateJob(path string) (*batch.Job, error) {
@@ -134,12 +171,30 @@ func parseAndValidateJob(path string) (*batch.Job, error) {
 	return &job, nil
 }
 
-// k8sClientSet creates the Kubernetes client set from the config.
-func k8sClientSet(kubeconfig string) (*kubernetes.Clientset, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+// k8sClientSet creates the Kubernetes client set from the config resolved by k8sConfig.
+func k8sClientSet(args *cli.Args) (*kubernetes.Clientset, error) {
+	config, err := k8sConfig(args)
 	if err != nil {
 		return nil, err
 	}
 
 	return kubernetes.NewForConfig(config)
 }
+
+// k8sConfig resolves the REST config to use, preferring an in-cluster config (requested via --in-cluster or
+// auto-detected via KUBERNETES_SERVICE_HOST, e.g. when kubejob itself runs as a Pod) over a kubeconfig file.
+// Otherwise it loads a kubeconfig using the same rules as kubectl: --kubeconfig if given, else $KUBECONFIG
+// (colon-separated, merged in order), else $HOME/.kube/config, with --context selecting a non-default context.
+func k8sConfig(args *cli.Args) (*rest.Config, error) {
+	if args.InCluster || os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return rest.InClusterConfig()
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if args.Kubeconfig != "" {
+		loadingRules.ExplicitPath = args.Kubeconfig
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: args.Context}
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+}
@@ -0,0 +1,52 @@
+package artifacts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+
+	"cloud.google.com/go/storage"
+)
+
+// GCSStore archives logs and the manifest to a Google Cloud Storage bucket.
+type GCSStore struct {
+	bucket *storage.BucketHandle
+	prefix string
+}
+
+// NewGCSStore returns a Store that archives artifacts below "<prefix>/<job>/..." in the given bucket, using Google
+// Application Default Credentials for authentication.
+func NewGCSStore(ctx context.Context, bucket, prefix string) (*GCSStore, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating GCS client: %v", err)
+	}
+	return &GCSStore{bucket: client.Bucket(bucket), prefix: prefix}, nil
+}
+
+func (s *GCSStore) Writer(job, pod, container string) (io.WriteCloser, error) {
+	return s.bucket.Object(s.key(job, pod, container+".log")).NewWriter(context.Background()), nil
+}
+
+func (s *GCSStore) WriteManifest(job string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling manifest: %v", err)
+	}
+
+	w := s.bucket.Object(s.key(job, "manifest.json")).NewWriter(context.Background())
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return fmt.Errorf("uploading manifest: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("uploading manifest: %v", err)
+	}
+	return nil
+}
+
+func (s *GCSStore) key(parts ...string) string {
+	return path.Join(append([]string{s.prefix}, parts...)...)
+}
@@ -0,0 +1,50 @@
+package artifacts
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// LocalStore archives logs and the manifest to a directory on the local filesystem.
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore returns a Store that archives artifacts below dir, creating it if necessary.
+func NewLocalStore(dir string) *LocalStore {
+	return &LocalStore{dir: dir}
+}
+
+func (s *LocalStore) Writer(job, pod, container string) (io.WriteCloser, error) {
+	path := filepath.Join(s.dir, job, pod, container+".log")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating log directory: %v", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating log file: %v", err)
+	}
+	return f, nil
+}
+
+func (s *LocalStore) WriteManifest(job string, m *Manifest) error {
+	dir := filepath.Join(s.dir, job)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating manifest directory: %v", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling manifest: %v", err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "manifest.json"), data, 0644); err != nil {
+		return fmt.Errorf("writing manifest: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package artifacts
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/egymgmbh/kubejob/pkg/job"
+)
+
+// Recorder consumes a job.Event stream and archives each container's log output to a Store, finalising with a
+// manifest describing every pod's phase, exit codes and log file sizes once Finalize is called.
+type Recorder struct {
+	store   Store
+	jobName string
+
+	mu       sync.Mutex
+	writers  map[string]io.WriteCloser // keyed by "<pod>/<container>"
+	sizes    map[string]int64          // keyed like writers
+	manifest Manifest
+}
+
+// NewRecorder returns a Recorder that archives the artifacts of jobName to store.
+func NewRecorder(store Store, jobName string) *Recorder {
+	return &Recorder{
+		store:   store,
+		jobName: jobName,
+		writers: make(map[string]io.WriteCloser),
+		sizes:   make(map[string]int64),
+		manifest: Manifest{
+			Job:  jobName,
+			Pods: make(map[string]*PodManifest),
+		},
+	}
+}
+
+// Handle records a single job.Event, ignoring any type it doesn't archive.
+func (r *Recorder) Handle(event job.Event) {
+	switch event := event.(type) {
+	case job.LogLine:
+		r.writeLine(event.Pod, event.Container, event.Line)
+	case job.PodStatus:
+		r.observePodStatus(event)
+	}
+}
+
+func (r *Recorder) writeLine(pod, container, line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := pod + "/" + container
+	w, ok := r.writers[key]
+	if !ok {
+		var err error
+		w, err = r.store.Writer(r.jobName, pod, container)
+		if err != nil {
+			log.Printf("artifacts: unable to open log writer for %s: %v", key, err)
+			return
+		}
+		r.writers[key] = w
+	}
+
+	n, err := io.WriteString(w, line)
+	if err != nil {
+		log.Printf("artifacts: unable to write log line for %s: %v", key, err)
+		return
+	}
+
+	r.sizes[key] += int64(n)
+	r.containerManifest(pod, container).LogBytes = r.sizes[key]
+}
+
+func (r *Recorder) observePodStatus(status job.PodStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pm := r.podManifest(status.Pod)
+	pm.Phase = string(status.Phase)
+	if status.StartTime != nil && pm.StartedAt == nil {
+		t := status.StartTime.Time
+		pm.StartedAt = &t
+	}
+
+	for _, cs := range status.ContainerStatuses {
+		cm := r.containerManifest(status.Pod, cs.Name)
+		term := cs.State.Terminated
+		if term == nil {
+			continue
+		}
+
+		exitCode := term.ExitCode
+		cm.ExitCode = &exitCode
+		if pm.FinishedAt == nil || term.FinishedAt.Time.After(*pm.FinishedAt) {
+			t := term.FinishedAt.Time
+			pm.FinishedAt = &t
+		}
+	}
+}
+
+func (r *Recorder) podManifest(pod string) *PodManifest {
+	pm, ok := r.manifest.Pods[pod]
+	if !ok {
+		pm = &PodManifest{Containers: make(map[string]*ContainerManifest)}
+		r.manifest.Pods[pod] = pm
+	}
+	return pm
+}
+
+func (r *Recorder) containerManifest(pod, container string) *ContainerManifest {
+	pm := r.podManifest(pod)
+	cm, ok := pm.Containers[container]
+	if !ok {
+		cm = &ContainerManifest{}
+		pm.Containers[container] = cm
+	}
+	return cm
+}
+
+// Finalize closes every open log writer and persists the manifest to the store.
+func (r *Recorder) Finalize() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var firstErr error
+	for key, w := range r.writers {
+		if err := w.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("closing log writer for %s: %v", key, err)
+		}
+	}
+
+	if err := r.store.WriteManifest(r.jobName, &r.manifest); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
@@ -0,0 +1,84 @@
+package artifacts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// S3Store archives logs and the manifest to an S3 bucket.
+type S3Store struct {
+	bucket   string
+	prefix   string
+	uploader *s3manager.Uploader
+}
+
+// NewS3Store returns a Store that archives artifacts below "<prefix>/<job>/..." in bucket, using sess for
+// authentication.
+func NewS3Store(sess *session.Session, bucket, prefix string) *S3Store {
+	return &S3Store{
+		bucket:   bucket,
+		prefix:   prefix,
+		uploader: s3manager.NewUploader(sess),
+	}
+}
+
+func (s *S3Store) Writer(job, pod, container string) (io.WriteCloser, error) {
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := s.uploader.Upload(&s3manager.UploadInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(s.key(job, pod, container+".log")),
+			Body:   pr,
+		})
+		pr.CloseWithError(err)
+		done <- err
+	}()
+
+	return &s3Writer{pw: pw, done: done}, nil
+}
+
+func (s *S3Store) WriteManifest(job string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling manifest: %v", err)
+	}
+
+	_, err = s.uploader.Upload(&s3manager.UploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(job, "manifest.json")),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("uploading manifest: %v", err)
+	}
+	return nil
+}
+
+func (s *S3Store) key(parts ...string) string {
+	return path.Join(append([]string{s.prefix}, parts...)...)
+}
+
+// s3Writer streams writes to S3 via an io.Pipe backing a concurrent s3manager.Upload call. Close blocks until the
+// upload has finished and returns its error, if any.
+type s3Writer struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+func (w *s3Writer) Close() error {
+	w.pw.Close()
+	return <-w.done
+}
@@ -0,0 +1,38 @@
+// Package artifacts archives the log output and outcome of a kubejob run to a pluggable backend (local filesystem,
+// S3 or GCS) so that it can be inspected after the job and its pods have been deleted.
+package artifacts
+
+import (
+	"io"
+	"time"
+)
+
+// Store is a pluggable backend for archiving a job's container logs and its final manifest. Implementations key
+// log files as "<job>/<pod>/<container>.log" and the manifest as "<job>/manifest.json".
+type Store interface {
+	// Writer returns a writer that archives the log output for the given job/pod/container. Callers must Close it
+	// once the container's log stream has ended.
+	Writer(job, pod, container string) (io.WriteCloser, error)
+	// WriteManifest persists the finished manifest for job.
+	WriteManifest(job string, m *Manifest) error
+}
+
+// Manifest describes the archived artifacts of a single job run.
+type Manifest struct {
+	Job  string                  `json:"job"`
+	Pods map[string]*PodManifest `json:"pods"`
+}
+
+// PodManifest describes the archived artifacts for one pod of a job.
+type PodManifest struct {
+	Phase      string                        `json:"phase"`
+	StartedAt  *time.Time                    `json:"startedAt,omitempty"`
+	FinishedAt *time.Time                    `json:"finishedAt,omitempty"`
+	Containers map[string]*ContainerManifest `json:"containers"`
+}
+
+// ContainerManifest describes the archived log file for one container of a pod.
+type ContainerManifest struct {
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	LogBytes int64  `json:"logBytes"`
+}
@@ -0,0 +1,216 @@
+package job
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// LogLine represents one line of log output from a container.
+type LogLine struct {
+	Pod       string // pod name
+	Container string // container name
+	Line      string // one line of log output
+}
+
+// LogStreamReconnect is reported whenever the log stream for a container had to be reopened after a recoverable
+// error, e.g. because the API server dropped the long-lived watch connection.
+type LogStreamReconnect struct {
+	Pod       string // pod name
+	Container string // container name
+	Attempt   int    // reconnect attempt, starting at 1
+	Cause     error  // error that triggered the reconnect
+}
+
+const (
+	// logStreamBaseBackoff is the backoff before the first reconnect attempt.
+	logStreamBaseBackoff = 500 * time.Millisecond
+	// logStreamMaxBackoff caps the exponential backoff between reconnect attempts.
+	logStreamMaxBackoff = 30 * time.Second
+	// logStreamMaxRetries is the number of reconnect attempts before giving up on a container's log stream.
+	logStreamMaxRetries = 10
+)
+
+// streamLogsForContainer reads all the logs from the specified container which must be part of the specified pod
+// and reports each line through events as a LogLine carrying the pod and container name. container may be empty in case pod has
+// only one container. wg.Done() is called when the end of the log stream is reached.
+//
+// If the stream breaks with a recoverable error (dropped connection, API throttling, ...) it is reopened with
+// PodLogOptions.SinceTime set to the last log line that was observed; see logResumePoint for how the unavoidable
+// duplicate lines that this can still produce are suppressed. Each reconnect is reported as a LogStreamReconnect
+// event. Terminal errors (pod or container gone for good) and exceeding logStreamMaxRetries are reported as an
+// error event instead.
+//
+// ctx is used to cancel the stream early, e.g. once the pod's primary container has terminated and the caller no
+// longer wants to wait for a sidecar's log stream to end on its own. Cancellation is not treated as an error.
+func streamLogsForContainer(ctx context.Context, cs *kubernetes.Clientset, pod *core.Pod, container string, wg *sync.WaitGroup, events chan<- Event) {
+	defer wg.Done()
+
+	var resume logResumePoint
+	for attempt := 0; ; attempt++ {
+		err := streamLogsOnce(ctx, cs, pod, container, &resume, events)
+		if err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if !isRecoverableLogStreamError(err) {
+			events <- fmt.Errorf("streamLogsForContainer: %v", err)
+			return
+		}
+		if attempt >= logStreamMaxRetries {
+			events <- fmt.Errorf("streamLogsForContainer: giving up after %d reconnect attempts: %v", attempt, err)
+			return
+		}
+
+		events <- LogStreamReconnect{Pod: pod.Name, Container: container, Attempt: attempt + 1, Cause: err}
+		select {
+		case <-time.After(logStreamBackoff(attempt)):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// logStreamBackoff returns the capped exponential backoff to wait before reconnect attempt number attempt (0-based).
+func logStreamBackoff(attempt int) time.Duration {
+	backoff := logStreamBaseBackoff << uint(attempt)
+	if backoff <= 0 || backoff > logStreamMaxBackoff {
+		return logStreamMaxBackoff
+	}
+	return backoff
+}
+
+// logResumePoint records enough of the last line streamLogsOnce emitted for a reconnect to request logs from
+// roughly that point via PodLogOptions.SinceTime, and to suppress the lines that are still likely to be re-sent
+// as duplicates.
+//
+// SinceTime is a metav1.Time, which serializes at one-second resolution, so the API server replays every line from
+// the start of Timestamp's second, not just the ones after Line. streamLogsOnce treats that as "reconnecting" and
+// drops lines up to and including one that matches Line before resuming normal delivery, falling back to resuming
+// as soon as a line's timestamp is later than Timestamp if Line is never seen again (e.g. because it scrolled
+// out of the container runtime's retained log buffer). This is a best-effort heuristic, not a guarantee: if a
+// container logged the exact same line more than once within that second, skipping ends at its first occurrence
+// and the lines after it, up to the reconnect point, are delivered a second time.
+type logResumePoint struct {
+	timestamp *time.Time // timestamp of the last line emitted, nil until a line with a timestamp has been emitted
+	line      string     // content (without the timestamp prefix) of the last line emitted
+}
+
+// streamLogsOnce opens a single log stream for container and reads from it until it ends (nil error) or breaks
+// (non-nil error). On a reconnect (resume.timestamp set from a previous attempt), logs are requested via
+// PodLogOptions.SinceTime and the replayed lines up to resume.line are suppressed, see logResumePoint. resume is
+// updated with the last line streamLogsOnce emits, so the caller can pass it back in on the next reconnect.
+func streamLogsOnce(ctx context.Context, cs *kubernetes.Clientset, pod *core.Pod, container string, resume *logResumePoint, events chan<- Event) error {
+	logOpts := core.PodLogOptions{
+		Follow:     true,
+		Container:  container,
+		Timestamps: true,
+	}
+	reconnecting := resume.timestamp != nil
+	var since time.Time
+	if reconnecting {
+		since = *resume.timestamp
+		sinceTime := meta.NewTime(since)
+		logOpts.SinceTime = &sinceTime
+	}
+
+	stream, err := cs.CoreV1().RESTClient().Get().
+		Namespace(pod.Namespace).
+		Resource("pods").
+		Name(pod.Name).
+		SubResource("log").
+		VersionedParams(&logOpts, scheme.ParameterCodec).
+		Context(ctx).
+		Stream()
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	skipping := reconnecting
+	reader := bufio.NewReader(stream)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if line != "" {
+			ts, rest := splitLogTimestamp(line)
+
+			emit := !skipping
+			if skipping && ts != nil {
+				switch {
+				case ts.After(since):
+					// past the point we reconnected from without seeing resume.line again: stop skipping rather
+					// than risk dropping logs that were never actually duplicated.
+					skipping, emit = false, true
+				case rest == resume.line:
+					// the duplicate of the last line emitted before reconnecting: drop it, resume after it.
+					skipping = false
+				}
+			}
+
+			if emit {
+				if ts != nil {
+					resume.timestamp = ts
+				}
+				resume.line = rest
+				events <- LogLine{Pod: pod.Name, Container: container, Line: rest}
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// splitLogTimestamp splits a log line obtained with PodLogOptions.Timestamps=true into its leading RFC3339Nano
+// timestamp and the remaining line content. It returns a nil timestamp if line doesn't start with one, which is
+// treated by the caller as "timestamp unchanged".
+func splitLogTimestamp(line string) (*time.Time, string) {
+	parts := strings.SplitN(line, " ", 2)
+	if len(parts) != 2 {
+		return nil, line
+	}
+	ts, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, line
+	}
+	return &ts, parts[1]
+}
+
+// isRecoverableLogStreamError reports whether err looks like a transient failure (dropped connection, API
+// throttling, server timeout, ...) that can be recovered from by reopening the log stream, as opposed to a terminal
+// condition such as the pod or container being gone for good.
+func isRecoverableLogStreamError(err error) bool {
+	if apierrors.IsNotFound(err) || apierrors.IsGone(err) {
+		return false
+	}
+	if apierrors.IsTooManyRequests(err) || apierrors.IsServerTimeout(err) || apierrors.IsInternalError(err) || apierrors.IsTimeout(err) {
+		return true
+	}
+	if err == io.ErrUnexpectedEOF || err == io.ErrClosedPipe {
+		return true
+	}
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+	return false
+}
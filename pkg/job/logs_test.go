@@ -0,0 +1,65 @@
+package job
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestSplitLogTimestamp(t *testing.T) {
+	data := []struct {
+		in      string
+		wantTs  bool
+		wantRem string
+	}{
+		{
+			"2020-01-02T15:04:05.000000001Z hello world\n",
+			true,
+			"hello world\n",
+		},
+		{
+			"hello world\n",
+			false,
+			"hello world\n",
+		},
+		{
+			"",
+			false,
+			"",
+		},
+	}
+
+	for _, d := range data {
+		ts, rem := splitLogTimestamp(d.in)
+		if (ts != nil) != d.wantTs {
+			t.Fatalf("%v: unexpected timestamp: %v", d, ts)
+		}
+		if rem != d.wantRem {
+			t.Fatalf("%v: unexpected remainder: %q", d, rem)
+		}
+	}
+}
+
+func TestIsRecoverableLogStreamError(t *testing.T) {
+	data := []struct {
+		in   error
+		want bool
+	}{
+		{io.ErrUnexpectedEOF, true},
+		{apierrors.NewTooManyRequests("busy", 1), true},
+		{apierrors.NewServerTimeout(schema.GroupResource{Resource: "pods"}, "get", 1), true},
+		{apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "pod"), false},
+		{apierrors.NewGone("gone"), false},
+		{errors.New("some other error"), false},
+	}
+
+	for _, d := range data {
+		got := isRecoverableLogStreamError(d.in)
+		if got != d.want {
+			t.Fatalf("%v: unexpected result: %v", d, got)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// JobStatus is reported whenever the watched Job's aggregate status changes, e.g. its count of succeeded or failed
+// pods. It allows callers to follow overall progress for Jobs whose Spec.Parallelism or Spec.Completions is greater
+// than one, where no single pod's phase reflects the job's outcome.
+type JobStatus struct {
+	batch.JobStatus
+}
+
+// PodStatus is reported whenever a tracked pod's status changes. It carries the pod name alongside the raw
+// core.PodStatus so that callers watching a job with several pods can tell which pod a status update belongs to.
+type PodStatus struct {
+	Pod string
+	core.PodStatus
+}
+
+// watchJob waits until job is done and reports the result (success or failure) through resultChan. The function
+// ensures that the log output of every pod belonging to job is reported through events and waits up to 10s for the
+// end of the logs after the job finished before reporting to resultChan. The send on resultChan does not block
+// forever: RunJob stops reading it as soon as ctx is done, so watchJob gives up on the send in that case too,
+// relying on ctx cancellation (see podWatcher) to also be what unblocks the log streaming it was waiting on.
+//
+// Jobs with Spec.Parallelism or Spec.Completions greater than one run many pods concurrently; watchJob tracks all of
+// them via a podWatcher and only considers the job done once job.Status.Succeeded reaches Spec.Completions or
+// job.Status.Failed exceeds Spec.BackoffLimit, rather than reacting to any single pod's terminal phase.
+func watchJob(ctx context.Context, cs *kubernetes.Clientset, job *batch.Job, resultChan chan<- result, events chan<- Event) {
+	// events is always closed before returning, however watchJob exits, so that a caller ranging over it (and
+	// anything gated on that range loop ending, e.g. main's consumerDone) is never left blocked.
+	if events != nil {
+		defer close(events)
+	}
+
+	podListOpts := meta.ListOptions{
+		LabelSelector: labelSelector(job.Spec.Selector.MatchLabels),
+		Watch:         true,
+	}
+	podWatch, err := cs.CoreV1().Pods(job.Namespace).Watch(podListOpts)
+	if err != nil {
+		select {
+		case resultChan <- result{false, fmt.Errorf("unable to watch pods: %v", err)}:
+		case <-ctx.Done():
+		}
+		return
+	}
+	defer podWatch.Stop()
+
+	jobListOpts := meta.ListOptions{
+		FieldSelector: "metadata.name=" + job.Name,
+		Watch:         true,
+	}
+	jobWatch, err := cs.BatchV1().Jobs(job.Namespace).Watch(jobListOpts)
+	if err != nil {
+		select {
+		case resultChan <- result{false, fmt.Errorf("unable to watch job: %v", err)}:
+		case <-ctx.Done():
+		}
+		return
+	}
+	defer jobWatch.Stop()
+
+	pw := newPodWatcher(ctx, cs, events)
+
+	var success bool
+	var loopErr error
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			loopErr = ctx.Err()
+			break loop
+		case watchEvent, ok := <-jobWatch.ResultChan():
+			if !ok {
+				break loop
+			}
+			j, ok := watchEvent.Object.(*batch.Job)
+			if !ok {
+				continue
+			}
+			if events != nil {
+				events <- JobStatus{j.Status}
+			}
+			var done bool
+			success, done = jobTerminalState(job, j.Status)
+			if done {
+				break loop
+			}
+		case watchEvent, ok := <-podWatch.ResultChan():
+			if !ok {
+				break loop
+			}
+			pod, ok := watchEvent.Object.(*core.Pod)
+			if !ok {
+				continue
+			}
+			pw.observe(pod, watchEvent.Type == watch.Deleted)
+		}
+	}
+
+	// Wait for every container's log stream to end, but no more than 10s. podCtx is derived from ctx (see
+	// newPodWatcher), so if we got here because ctx was canceled, every stream is already unwinding and this
+	// should return quickly; the timeout is only a backstop in case a stream doesn't.
+	select {
+	case <-pw.done():
+	case <-time.After(10 * time.Second):
+		if loopErr == nil {
+			loopErr = errors.New("timeout waiting for end of logs")
+		}
+	}
+
+	// RunJob stops reading resultChan as soon as ctx is done (see job.go), so don't block forever if that already
+	// happened; events is closed by the deferred close above either way.
+	select {
+	case resultChan <- result{success, loopErr}:
+	case <-ctx.Done():
+	}
+}
+
+// jobTerminalState reports whether job has reached a terminal state given status, and if so whether it succeeded.
+// A job succeeds once status.Succeeded reaches its completion count (Spec.Completions, defaulting to 1) and fails
+// once status.Failed exceeds Spec.BackoffLimit (defaulting to 6, matching the Kubernetes API default).
+func jobTerminalState(job *batch.Job, status batch.JobStatus) (success, done bool) {
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+	if status.Succeeded >= completions {
+		return true, true
+	}
+
+	backoffLimit := int32(6)
+	if job.Spec.BackoffLimit != nil {
+		backoffLimit = *job.Spec.BackoffLimit
+	}
+	if status.Failed > backoffLimit {
+		return false, true
+	}
+
+	return false, false
+}
+
+// primaryContainerAnnotation, when set on a job's pod template, names the container whose termination is used to
+// stop waiting on any remaining sidecar containers' log streams. If unset, the pod's first container
+// (pod.Spec.Containers[0]) is treated as the primary container. The job's overall success or failure is still
+// decided by the Job's own status (see jobTerminalState), not by this container's exit code.
+const primaryContainerAnnotation = "kubejob.egym.com/primary-container"
+
+// primaryContainerName returns the name of pod's primary container, see primaryContainerAnnotation. It returns ""
+// for a pod without any containers.
+func primaryContainerName(pod *core.Pod) string {
+	if name := pod.Annotations[primaryContainerAnnotation]; name != "" {
+		return name
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
+
+// podWatcher tracks the per-pod state (phase, log streaming) of every pod belonging to a job and starts/reaps the
+// log streaming goroutines for each pod as it appears and disappears.
+type podWatcher struct {
+	ctx    context.Context // parent of every trackedPod's podCtx, canceled when watchJob's caller cancels
+	cs     *kubernetes.Clientset
+	events chan<- Event
+
+	mu   sync.Mutex
+	pods map[string]*trackedPod
+}
+
+// trackedPod is the podWatcher's bookkeeping for a single pod. Each init and regular container is streamed as soon
+// as it individually reports Running or Terminated, since init container logs in particular are unavailable any
+// earlier and aren't covered by the pod's overall phase.
+type trackedPod struct {
+	phase core.PodPhase
+
+	started        bool // whether any container's log stream has been started for this pod
+	initContainers map[string]bool
+	containers     map[string]bool
+	wg             sync.WaitGroup
+
+	// podCtx is derived from podWatcher.ctx and canceled once the pod is deleted or watchJob's caller cancels,
+	// tearing down every container's log stream since there is nothing left to read. sidecarCtx is derived from
+	// podCtx and additionally canceled once the primary container terminates, so only sidecar (and init) container
+	// streams are cut short, never the primary's own still-draining stream.
+	podCtx           context.Context
+	cancelPod        context.CancelFunc
+	sidecarCtx       context.Context
+	cancelSidecar    context.CancelFunc
+	sidecarsCanceled bool
+}
+
+func newPodWatcher(ctx context.Context, cs *kubernetes.Clientset, events chan<- Event) *podWatcher {
+	return &podWatcher{
+		ctx:    ctx,
+		cs:     cs,
+		events: events,
+		pods:   make(map[string]*trackedPod),
+	}
+}
+
+// observe records a pod status update. It starts streaming the logs of each init and regular container as soon as
+// it is ready, cancels any still-streaming sidecar containers once the pod's primary container has terminated, and
+// forgets the pod once it is deleted.
+func (w *podWatcher) observe(pod *core.Pod, deleted bool) {
+	if w.events != nil {
+		w.events <- PodStatus{Pod: pod.Name, PodStatus: pod.Status}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if deleted {
+		if tp := w.pods[pod.Name]; tp != nil && tp.cancelPod != nil {
+			tp.cancelPod()
+		}
+		delete(w.pods, pod.Name)
+		return
+	}
+
+	tp := w.pods[pod.Name]
+	if tp == nil {
+		tp = &trackedPod{
+			initContainers: make(map[string]bool),
+			containers:     make(map[string]bool),
+		}
+		w.pods[pod.Name] = tp
+	}
+	tp.phase = pod.Status.Phase
+
+	if w.events == nil {
+		return
+	}
+
+	primary := primaryContainerName(pod)
+
+	for _, cs := range pod.Status.InitContainerStatuses {
+		w.startContainerStream(tp, pod, cs.Name, cs, tp.initContainers, false)
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		w.startContainerStream(tp, pod, cs.Name, cs, tp.containers, cs.Name == primary)
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == primary && cs.State.Terminated != nil {
+			w.cancelSidecars(tp)
+			break
+		}
+	}
+}
+
+// startContainerStream starts streaming logs for the container described by status, unless it has already been
+// started or isn't ready yet (a container's logs are only available once it is Running or Terminated). started
+// tracks per-container progress for either tp.initContainers or tp.containers. isPrimary excludes the stream from
+// cancelSidecars, so the primary container's own log stream is never cut short by its own termination.
+func (w *podWatcher) startContainerStream(tp *trackedPod, pod *core.Pod, container string, status core.ContainerStatus, started map[string]bool, isPrimary bool) {
+	if started[container] || (status.State.Running == nil && status.State.Terminated == nil) {
+		return
+	}
+	started[container] = true
+
+	if tp.podCtx == nil {
+		tp.podCtx, tp.cancelPod = context.WithCancel(w.ctx)
+		tp.sidecarCtx, tp.cancelSidecar = context.WithCancel(tp.podCtx)
+	}
+	ctx := tp.sidecarCtx
+	if isPrimary {
+		ctx = tp.podCtx
+	}
+
+	tp.started = true
+	tp.wg.Add(1)
+	go streamLogsForContainer(ctx, w.cs, pod, container, &tp.wg, w.events)
+}
+
+// cancelSidecars stops any non-primary container log streams still running for tp instead of waiting for them to
+// end on their own, e.g. once the pod's primary container has terminated. The primary container's own stream is
+// never affected, so it can still deliver its final log lines.
+func (w *podWatcher) cancelSidecars(tp *trackedPod) {
+	if tp.sidecarsCanceled || tp.cancelSidecar == nil {
+		return
+	}
+	tp.sidecarsCanceled = true
+	tp.cancelSidecar()
+}
+
+// done returns a channel that is closed once log streaming has finished for every pod observed so far.
+func (w *podWatcher) done() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		w.mu.Lock()
+		var wgs []*sync.WaitGroup
+		for _, tp := range w.pods {
+			if tp.started {
+				wgs = append(wgs, &tp.wg)
+			}
+		}
+		w.mu.Unlock()
+
+		for _, wg := range wgs {
+			wg.Wait()
+		}
+		close(done)
+	}()
+	return done
+}
+
+// labelSelector converts a label map (as used in the job spec) into a label query as used in the API.
+func labelSelector(labels map[string]string) string {
+	var buf bytes.Buffer
+	for k, v := range labels {
+		fmt.Fprintf(&buf, "%s=%s,", k, v)
+	}
+	if buf.Len() > 0 {
+		buf.Truncate(buf.Len() - 1)
+	}
+	return buf.String()
+}
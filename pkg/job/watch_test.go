@@ -0,0 +1,87 @@
+package job
+
+import (
+	"testing"
+
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32ptr(i int32) *int32 { return &i }
+
+func TestJobTerminalState(t *testing.T) {
+	data := []struct {
+		job         *batch.Job
+		status      batch.JobStatus
+		wantSuccess bool
+		wantDone    bool
+	}{
+		{
+			&batch.Job{},
+			batch.JobStatus{Succeeded: 1},
+			true,
+			true,
+		},
+		{
+			&batch.Job{Spec: batch.JobSpec{Completions: int32ptr(3)}},
+			batch.JobStatus{Succeeded: 2},
+			false,
+			false,
+		},
+		{
+			&batch.Job{Spec: batch.JobSpec{Completions: int32ptr(3)}},
+			batch.JobStatus{Succeeded: 3},
+			true,
+			true,
+		},
+		{
+			&batch.Job{Spec: batch.JobSpec{BackoffLimit: int32ptr(0)}},
+			batch.JobStatus{Failed: 1},
+			false,
+			true,
+		},
+		{
+			&batch.Job{},
+			batch.JobStatus{Failed: 1},
+			false,
+			false,
+		},
+	}
+
+	for _, d := range data {
+		success, done := jobTerminalState(d.job, d.status)
+		if success != d.wantSuccess || done != d.wantDone {
+			t.Fatalf("%v: unexpected result: success=%v done=%v", d, success, done)
+		}
+	}
+}
+
+func TestPrimaryContainerName(t *testing.T) {
+	data := []struct {
+		pod  *core.Pod
+		want string
+	}{
+		{
+			&core.Pod{Spec: core.PodSpec{Containers: []core.Container{{Name: "main"}, {Name: "sidecar"}}}},
+			"main",
+		},
+		{
+			&core.Pod{
+				ObjectMeta: meta.ObjectMeta{Annotations: map[string]string{primaryContainerAnnotation: "sidecar"}},
+				Spec:       core.PodSpec{Containers: []core.Container{{Name: "main"}, {Name: "sidecar"}}},
+			},
+			"sidecar",
+		},
+		{
+			&core.Pod{},
+			"",
+		},
+	}
+
+	for _, d := range data {
+		if got := primaryContainerName(d.pod); got != d.want {
+			t.Fatalf("%v: unexpected result: %v", d, got)
+		}
+	}
+}